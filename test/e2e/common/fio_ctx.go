@@ -0,0 +1,62 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// FioExecError wraps a failed fio run with its stderr captured separately
+// from stdout, so callers can pattern-match fio's diagnostics (e.g. a
+// verify failure) without wading through the json/status output on stdout.
+type FioExecError struct {
+	Stderr []byte
+	cause  error
+}
+
+func (e *FioExecError) Error() string { return e.cause.Error() }
+func (e *FioExecError) Unwrap() error { return e.cause }
+
+// RunFioCtx behaves like RunFio, except it honours ctx: if ctx is cancelled
+// before the fio run completes on its own, a SIGTERM is delivered to the fio
+// process inside podName (via a second `kubectl exec`, so fio gets a chance
+// to flush its report) rather than tearing down the exec session outright.
+func RunFioCtx(ctx context.Context, podName string, runtime int, fioFile string, args ...string) ([]byte, error) {
+	fioArgs := append([]string{fioFile, fmt.Sprintf("--runtime=%d", runtime), "--time_based"}, args...)
+	cmd := exec.Command("kubectl", append([]string{"exec", podName, "--", "fio"}, fioArgs...)...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	combined := func() []byte { return append(append([]byte{}, stdout.Bytes()...), stderr.Bytes()...) }
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start fio on pod %s: %v", podName, err)
+	}
+
+	waitC := make(chan error, 1)
+	go func() { waitC <- cmd.Wait() }()
+
+	var err error
+	select {
+	case err = <-waitC:
+	case <-ctx.Done():
+		if termErr := terminateFio(podName); termErr != nil {
+			return combined(), fmt.Errorf("failed to terminate fio on pod %s: %v", podName, termErr)
+		}
+		if err = <-waitC; err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	if err != nil {
+		err = &FioExecError{Stderr: stderr.Bytes(), cause: err}
+	}
+	return combined(), err
+}
+
+// terminateFio sends SIGTERM to the fio process running inside podName.
+func terminateFio(podName string) error {
+	return exec.Command("kubectl", "exec", podName, "--", "pkill", "-TERM", "fio").Run()
+}
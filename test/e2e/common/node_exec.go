@@ -0,0 +1,78 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// NodeExecNamespace and NodeExecPodSelector identify the already-running,
+// privileged per-node pod (the Mayastor io-engine daemonset by default)
+// that RunOnNode execs into to read host files/commands. Override these if
+// diskstats should instead be gathered via a different daemonset.
+var (
+	NodeExecNamespace   = "mayastor"
+	NodeExecPodSelector = "app=io-engine"
+)
+
+var (
+	nodeExecPodCacheMu sync.Mutex
+	nodeExecPodCache   = map[string]string{}
+)
+
+// RunOnNode runs cmd on the named Kubernetes node by exec'ing into the
+// already-running NodeExecPodSelector pod scheduled on that node. Unlike
+// `kubectl debug node/...`, this creates no new Pod objects, so it's cheap
+// and safe to call at a sub-minute polling cadence without leaking debug
+// pods into the cluster.
+func RunOnNode(nodeName string, cmd ...string) ([]byte, error) {
+	podName, err := nodeExecPodName(nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"exec", "-n", NodeExecNamespace, podName, "--"}, cmd...)
+	c := exec.Command("kubectl", args...)
+	var output bytes.Buffer
+	c.Stdout = &output
+	c.Stderr = &output
+
+	if err := c.Run(); err != nil {
+		return output.Bytes(), fmt.Errorf("failed to run %v on node %s (pod %s): %v", cmd, nodeName, podName, err)
+	}
+	return output.Bytes(), nil
+}
+
+// nodeExecPodName returns the name of the NodeExecPodSelector pod scheduled
+// on nodeName, caching the lookup since the daemonset pod on a node doesn't
+// change for the life of a soak run.
+func nodeExecPodName(nodeName string) (string, error) {
+	nodeExecPodCacheMu.Lock()
+	podName, ok := nodeExecPodCache[nodeName]
+	nodeExecPodCacheMu.Unlock()
+	if ok {
+		return podName, nil
+	}
+
+	out, err := exec.Command("kubectl", "get", "pods",
+		"-n", NodeExecNamespace,
+		"-l", NodeExecPodSelector,
+		"--field-selector", "spec.nodeName="+nodeName,
+		"-o", "jsonpath={.items[0].metadata.name}",
+	).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find a %q pod on node %s: %v", NodeExecPodSelector, nodeName, err)
+	}
+
+	podName = strings.TrimSpace(string(out))
+	if podName == "" {
+		return "", fmt.Errorf("no %q pod found on node %s", NodeExecPodSelector, nodeName)
+	}
+
+	nodeExecPodCacheMu.Lock()
+	nodeExecPodCache[nodeName] = podName
+	nodeExecPodCacheMu.Unlock()
+	return podName, nil
+}
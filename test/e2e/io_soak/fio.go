@@ -3,6 +3,7 @@ package io_soak
 import (
 	"e2e-basic/common"
 
+	"context"
 	"fmt"
 	"io/ioutil"
 	"time"
@@ -10,42 +11,66 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-// This table of duty cycles is guesstimates and bear no relation to real loads.
-// TODO: make configurable
-var FioDutyCycles = []struct {
-	thinkTime       int
-	thinkTimeBlocks int
-}{
-	{500000, 1000},  // 0.5 second, 1000 blocks
-	{750000, 1000},  // 0.75 second, 1000 blocks
-	{1000000, 2000}, // 1 second, 2000 blocks
-	{1250000, 2000}, // 1.25 seconds, 2000 blocks
-	{1500000, 3000}, // 1.5  seconds, 3000 blocks
-	{1750000, 3000}, // 1.75  seconds, 3000 blocks
-	{2000000, 4000}, // 2  seconds, 4000 blocks
-}
-
 const fixedDuration = 60
 
+// statusStreamInterval is the --status-interval passed to fio so that
+// RunIoSoakFio gets a steady stream of interim json results over the life
+// of a single run, rather than just a final one.
+const statusStreamInterval = 1
+
+// UseFixedIterationFio preserves the old behavior of restarting fio every
+// fixedDuration seconds for the life of the run, rather than launching it
+// once for the full duration. It exists for backwards compatibility with
+// callers that relied on the periodic restart; new soak runs should leave
+// this false so fio's cumulative counters and steady-state caches survive
+// the whole run.
+var UseFixedIterationFio = false
+
+// fioArgsForProfile builds the fio command line overrides for the given
+// profile. --thinktime and --thinktime_blocks are always passed, even when
+// zero; the other fields are omitted when left at their zero value so that
+// the job file's own defaults apply.
+func fioArgsForProfile(profile FioProfile) []string {
+	args := []string{
+		fmt.Sprintf("--thinktime=%d", profile.ThinkTime),
+		fmt.Sprintf("--thinktime_blocks=%d", profile.ThinkTimeBlocks),
+	}
+	if profile.Rw != "" {
+		args = append(args, fmt.Sprintf("--rw=%s", profile.Rw))
+	}
+	if profile.BlockSize != "" {
+		args = append(args, fmt.Sprintf("--bs=%s", profile.BlockSize))
+	}
+	if profile.IoDepth != 0 {
+		args = append(args, fmt.Sprintf("--iodepth=%d", profile.IoDepth))
+	}
+	if profile.NumJobs != 0 {
+		args = append(args, fmt.Sprintf("--numjobs=%d", profile.NumJobs))
+	}
+	if profile.Size != "" {
+		args = append(args, fmt.Sprintf("--size=%s", profile.Size))
+	}
+	args = append(args, verifyArgsForProfile(profile)...)
+	return args
+}
+
 // see https://fio.readthedocs.io/en/latest/fio_doc.html#i-o-rate
-// run fio in a loop of fixed duration to fulfill a larger duration,
-// this to facilitate a relatively timely termination when an error
-// occurs elsewhere.
+// ctx - cancelled to abort fio early, e.g. when another pod's run has already failed.
 // podName - name of the fio pod
 // duration - time in seconds to run fio
-// thinktime -  usecs, stall the job for the specified period of time after an I/O has completed before issuing the next
-// thinktime_blocks - how many blocks to issue, before waiting thinktime usecs.
+// profileIx - index into FioProfiles selecting the workload to apply, wrapping if out of range.
 // rawBlock - false for filesystem volumes, true for raw block mounts.
-func RunIoSoakFio(podName string, duration time.Duration, thinkTime int, thinkTimeBlocks int, rawBlock bool, doneC chan<- string, errC chan<- error) {
-	secs := int(duration.Seconds())
-	argThinkTime := fmt.Sprintf("--thinktime=%d", thinkTime)
-	argThinkTimeBlocks := fmt.Sprintf("--thinktime_blocks=%d", thinkTimeBlocks)
+// resultC - per-iteration parsed fio metrics, for SLO assertions and the summary table.
+func RunIoSoakFio(ctx context.Context, podName string, duration time.Duration, profileIx int, rawBlock bool, doneC chan<- string, errC chan<- error, resultC chan<- FioResult) {
+	profile := FioProfiles[profileIx%len(FioProfiles)]
+	fioArgs := fioArgsForProfile(profile)
 
 	logf.Log.Info("Running fio",
 		"pod", podName,
 		"duration", duration,
-		"thinktime", thinkTime,
-		"thinktime_blocks", thinkTimeBlocks,
+		"profile", profile.Name,
+		"thinktime", profile.ThinkTime,
+		"thinktime_blocks", profile.ThinkTimeBlocks,
 		"rawBlock", rawBlock,
 	)
 
@@ -56,8 +81,118 @@ func RunIoSoakFio(podName string, duration time.Duration, thinkTime int, thinkTi
 		fioFile = common.FioFsFilename
 	}
 
+	if UseFixedIterationFio {
+		runIoSoakFioFixedIterations(podName, duration, profile, fioArgs, fioFile, doneC, errC, resultC)
+		return
+	}
+	runIoSoakFioStreaming(ctx, podName, duration, profile, fioArgs, fioFile, doneC, errC, resultC)
+}
+
+// runIoSoakFioStreaming launches fio once for the full duration, streaming
+// --status-interval json results onto resultC as they land, and aborts the
+// run by sending SIGTERM to the fio process in podName if ctx is cancelled
+// before it completes on its own.
+func runIoSoakFioStreaming(ctx context.Context, podName string, duration time.Duration, profile FioProfile, fioArgs []string, fioFile string, doneC chan<- string, errC chan<- error, resultC chan<- FioResult) {
+	secs := int(duration.Seconds())
+	if profile.Runtime > 0 {
+		secs = profile.Runtime
+	}
+	jsonPath := fmt.Sprintf("/tmp/%s.json", podName)
+	runArgs := append(append([]string{}, fioArgs...),
+		fmt.Sprintf("--status-interval=%d", statusStreamInterval),
+		"--output-format=json",
+		fmt.Sprintf("--output=%s", jsonPath),
+	)
+
+	streamCtx, stopStream := context.WithCancel(ctx)
+	seen := 0
+	streamDone := make(chan struct{})
+	go streamFioJSONStatus(streamCtx, podName, jsonPath, &seen, resultC, streamDone)
+
+	logf.Log.Info("run fio",
+		"pod", podName,
+		"duration", secs,
+		"profile", profile.Name,
+		"fioFile", fioFile,
+	)
+	output, err := common.RunFioCtx(ctx, podName, secs, fioFile, runArgs...)
+
+	// Stop the poller and wait for it to actually return before touching
+	// seen again, then do one last synchronous read: fio's final
+	// --status-interval document, written as the process exits with the
+	// run's full cumulative counters, is otherwise almost always missed by
+	// the 1s ticker below, since the process typically exits well inside a
+	// single tick.
+	stopStream()
+	<-streamDone
+	flushNewFioDocs(podName, jsonPath, &seen, resultC)
+
+	//TODO: for now shove the raw output into /tmp too, for debugging failed runs
+	_ = ioutil.WriteFile("/tmp/"+podName+".out", output, 0644)
+	if err != nil {
+		if profile.Verify {
+			err = newVerifyError(podName, output, err)
+		}
+		logf.Log.Info("Abort running fio", "pod", podName, "error", err)
+		errC <- err
+		return
+	}
+
+	logf.Log.Info("Finished running fio", "pod", podName, "duration", duration)
+	doneC <- podName
+}
+
+// streamFioJSONStatus polls path once a second for newly appended fio
+// --status-interval json documents and sends each one, parsed, onto
+// resultC. It returns once ctx is done, closing done so a caller can do
+// one final flushNewFioDocs call afterwards without racing seen.
+func streamFioJSONStatus(ctx context.Context, podName string, path string, seen *int, resultC chan<- FioResult, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(statusStreamInterval * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			flushNewFioDocs(podName, path, seen, resultC)
+		}
+	}
+}
+
+// flushNewFioDocs reads path and sends onto resultC any complete fio
+// --status-interval json documents beyond the *seen'th, advancing *seen.
+// It is used both by streamFioJSONStatus's own ticker and, once the
+// poller has confirmed it stopped, for one last synchronous read after
+// fio exits.
+func flushNewFioDocs(podName string, path string, seen *int, resultC chan<- FioResult) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	docs := decodeFioJSONStatusStream(data)
+	for ix := *seen; ix < len(docs); ix++ {
+		resultC <- fioResultFromJob(podName, ix+1, docs[ix].TimestampMs, docs[ix].Jobs[0])
+	}
+	*seen = len(docs)
+}
+
+// runIoSoakFioFixedIterations is the pre-refactor behavior, preserved for
+// backwards compatibility behind UseFixedIterationFio: it runs fio in a
+// loop of fixed duration to fulfill a larger duration, restarting fio every
+// fixedDuration seconds. This was originally done to facilitate a timely
+// abort when an error occurs elsewhere, at the cost of resetting fio's
+// cumulative counters and cold-starting caches on every restart.
+func runIoSoakFioFixedIterations(podName string, duration time.Duration, profile FioProfile, fioArgs []string, fioFile string, doneC chan<- string, errC chan<- error, resultC chan<- FioResult) {
+	secs := int(duration.Seconds())
+	iterationLength := fixedDuration
+	if profile.Runtime > 0 {
+		iterationLength = profile.Runtime
+	}
+
 	for ix := 1; secs > 0; ix++ {
-		runtime := fixedDuration
+		runtime := iterationLength
 		if runtime > secs {
 			runtime = secs
 		}
@@ -67,22 +202,35 @@ func RunIoSoakFio(podName string, duration time.Duration, thinkTime int, thinkTi
 			"iteration", ix,
 			"pod", podName,
 			"duration", runtime,
-			"thinktime", thinkTime,
-			"thinktime_blocks", thinkTimeBlocks,
-			"rawBlock", rawBlock,
+			"profile", profile.Name,
 			"fioFile", fioFile,
 		)
-		output, err := common.RunFio(podName, runtime, fioFile, argThinkTime, argThinkTimeBlocks )
+		jsonPath := fmt.Sprintf("/tmp/%s-%d.json", podName, ix)
+		iterArgs := append(append([]string{}, fioArgs...),
+			"--output-format=json",
+			fmt.Sprintf("--output=%s", jsonPath),
+		)
+		output, err := common.RunFio(podName, runtime, fioFile, iterArgs...)
 
-		//TODO: for now shove the output into /tmp
+		//TODO: for now shove the raw output into /tmp too, for debugging failed runs
 		_ = ioutil.WriteFile("/tmp/"+podName+".out", output, 0644)
-		//logf.Log.Info(string(output))
 		if err != nil {
+			if profile.Verify {
+				err = newVerifyError(podName, output, err)
+			}
 			logf.Log.Info("Abort running fio", "pod", podName, "error", err)
 			errC <- err
 			return
 		}
+
+		result, err := readFioJSONResult(podName, ix, jsonPath)
+		if err != nil {
+			logf.Log.Info("Abort running fio", "pod", podName, "error", err)
+			errC <- err
+			return
+		}
+		resultC <- result
 	}
 	logf.Log.Info("Finished running fio", "pod", podName, "duration", duration)
 	doneC <- podName
-}
\ No newline at end of file
+}
@@ -0,0 +1,59 @@
+package io_soak
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestFlushNewFioDocsAdvancesSeen reproduces the final-read-after-exit case:
+// the file already holds documents the streamer's ticker saw (seen=1), plus
+// a newly appended one (e.g. fio's last, most complete status-interval
+// document written as the process exits) that a caller must still be able
+// to pick up with one more flushNewFioDocs call.
+func TestFlushNewFioDocsAdvancesSeen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fio.json")
+	if err := ioutil.WriteFile(path, []byte(sampleFioJSON), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	resultC := make(chan FioResult, 4)
+	seen := 0
+	flushNewFioDocs("fio-pod-0", path, &seen, resultC)
+	if seen != 1 {
+		t.Fatalf("expected seen=1 after first flush, got %d", seen)
+	}
+	if len(resultC) != 1 {
+		t.Fatalf("expected 1 result delivered, got %d", len(resultC))
+	}
+	<-resultC
+
+	// A second flush against an unchanged file must not redeliver the
+	// document already seen.
+	flushNewFioDocs("fio-pod-0", path, &seen, resultC)
+	if len(resultC) != 0 {
+		t.Fatalf("expected no duplicate results, got %d", len(resultC))
+	}
+
+	// fio appends its final cumulative document at process exit; the next
+	// flush must pick it up even though the ticker never ran again.
+	if err := ioutil.WriteFile(path, []byte(sampleFioJSON+sampleFioJSON), 0644); err != nil {
+		t.Fatalf("failed to append fixture: %v", err)
+	}
+	flushNewFioDocs("fio-pod-0", path, &seen, resultC)
+	if seen != 2 {
+		t.Fatalf("expected seen=2 after final flush, got %d", seen)
+	}
+	if len(resultC) != 1 {
+		t.Fatalf("expected the final document delivered, got %d", len(resultC))
+	}
+}
+
+func TestFlushNewFioDocsMissingFile(t *testing.T) {
+	resultC := make(chan FioResult, 1)
+	seen := 0
+	flushNewFioDocs("fio-pod-0", filepath.Join(t.TempDir(), "missing.json"), &seen, resultC)
+	if seen != 0 || len(resultC) != 0 {
+		t.Errorf("expected no-op for a missing file, got seen=%d len=%d", seen, len(resultC))
+	}
+}
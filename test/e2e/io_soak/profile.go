@@ -0,0 +1,101 @@
+package io_soak
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// FioProfile describes a single fio workload to apply to a pod for the
+// duration of its duty cycle. ThinkTime and ThinkTimeBlocks are always
+// passed to fio, even when zero; the other fields are omitted from the fio
+// command line when left at their zero value, so the job file's own
+// defaults apply.
+type FioProfile struct {
+	// Name is a human readable label used only for logging.
+	Name string `json:"name,omitempty"`
+	// Rw is the fio rw mode: read, write, randread, randwrite or rw.
+	Rw string `json:"rw,omitempty"`
+	// BlockSize is the fio bs value, e.g. "4k" or "1m".
+	BlockSize string `json:"blockSize,omitempty"`
+	IoDepth   int    `json:"ioDepth,omitempty"`
+	NumJobs   int    `json:"numJobs,omitempty"`
+	// ThinkTime, in usecs, stalls the job for the specified period of time
+	// after an I/O has completed before issuing the next.
+	ThinkTime int `json:"thinkTime"`
+	// ThinkTimeBlocks is how many blocks to issue before waiting ThinkTime usecs.
+	ThinkTimeBlocks int `json:"thinkTimeBlocks"`
+	// Size is the fio size value, e.g. "1g". Leave empty to use the job file default.
+	Size string `json:"size,omitempty"`
+	// Runtime, in seconds, overrides the length of a fio invocation for this
+	// profile: in the streaming runner it overrides the whole run length
+	// (in place of the caller-supplied duration); in the legacy
+	// fixed-iteration runner it overrides the length of each iteration (in
+	// place of fixedDuration). Zero keeps each runner's default: the
+	// caller-supplied duration for the streaming runner, fixedDuration per
+	// iteration for the fixed-iteration runner.
+	Runtime int `json:"runtime,omitempty"`
+
+	// Verify enables fio's data-integrity checking (--do_verify=1,
+	// --verify=crc32c, --verify_fatal=1) so the soak run fails on silent
+	// corruption instead of only measuring throughput. Works for both
+	// filesystem and raw-block targets.
+	Verify bool `json:"verify,omitempty"`
+	// VerifyBacklog is the fio --verify_backlog value, i.e. how many writes
+	// to buffer before verifying them. Defaults to defaultVerifyBacklog when
+	// Verify is set and this is left at zero.
+	VerifyBacklog int `json:"verifyBacklog,omitempty"`
+	// VerifyPattern is the fio --verify_pattern value. Only meaningful for
+	// randwrite workloads; defaults to defaultVerifyPattern when Verify is
+	// set, Rw is "randwrite" and this is left empty.
+	VerifyPattern string `json:"verifyPattern,omitempty"`
+}
+
+// FioDutyCycles is the default set of duty cycles, used when no profile file
+// is supplied. It is a guesstimate and bears no relation to real loads.
+var FioDutyCycles = []FioProfile{
+	{ThinkTime: 500000, ThinkTimeBlocks: 1000},  // 0.5 second, 1000 blocks
+	{ThinkTime: 750000, ThinkTimeBlocks: 1000},  // 0.75 second, 1000 blocks
+	{ThinkTime: 1000000, ThinkTimeBlocks: 2000}, // 1 second, 2000 blocks
+	{ThinkTime: 1250000, ThinkTimeBlocks: 2000}, // 1.25 seconds, 2000 blocks
+	{ThinkTime: 1500000, ThinkTimeBlocks: 3000}, // 1.5  seconds, 3000 blocks
+	{ThinkTime: 1750000, ThinkTimeBlocks: 3000}, // 1.75  seconds, 3000 blocks
+	{ThinkTime: 2000000, ThinkTimeBlocks: 4000}, // 2  seconds, 4000 blocks
+}
+
+// FioProfiles is the active list of workload profiles that RunIoSoakFio picks
+// from. It defaults to FioDutyCycles and can be replaced by LoadFioProfiles.
+var FioProfiles = FioDutyCycles
+
+// LoadFioProfiles reads a list of FioProfile entries from a YAML or JSON file
+// (the extension must be ".json", ".yaml" or ".yml"; all three are parsed by
+// the same YAML decoder, which also accepts JSON) and replaces
+// FioProfiles with its contents. This lets a soak run be pointed at a profile
+// file describing a realistic mix of workloads instead of the built-in
+// FioDutyCycles table.
+func LoadFioProfiles(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read fio profile file %s: %v", path, err)
+	}
+
+	var profiles []FioProfile
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json", ".yaml", ".yml":
+		if err := yaml.Unmarshal(content, &profiles); err != nil {
+			return fmt.Errorf("failed to parse fio profile file %s: %v", path, err)
+		}
+	default:
+		return fmt.Errorf("unsupported fio profile file extension %q, want .json, .yaml or .yml", ext)
+	}
+
+	if len(profiles) == 0 {
+		return fmt.Errorf("fio profile file %s defines no profiles", path)
+	}
+
+	FioProfiles = profiles
+	return nil
+}
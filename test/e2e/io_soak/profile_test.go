@@ -0,0 +1,95 @@
+package io_soak
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempProfileFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestLoadFioProfilesJSON(t *testing.T) {
+	defer func() { FioProfiles = FioDutyCycles }()
+
+	path := writeTempProfileFile(t, "profiles.json", `[
+		{"name": "randwrite-4k", "rw": "randwrite", "blockSize": "4k", "ioDepth": 16},
+		{"name": "seq-read-1m", "rw": "read", "blockSize": "1m"}
+	]`)
+
+	if err := LoadFioProfiles(path); err != nil {
+		t.Fatalf("LoadFioProfiles returned error: %v", err)
+	}
+	if len(FioProfiles) != 2 {
+		t.Fatalf("expected 2 profiles, got %d", len(FioProfiles))
+	}
+	if FioProfiles[0].Name != "randwrite-4k" || FioProfiles[0].IoDepth != 16 {
+		t.Errorf("unexpected first profile: %+v", FioProfiles[0])
+	}
+}
+
+func TestLoadFioProfilesYAML(t *testing.T) {
+	defer func() { FioProfiles = FioDutyCycles }()
+
+	path := writeTempProfileFile(t, "profiles.yaml", `
+- name: randwrite-4k
+  rw: randwrite
+  blockSize: 4k
+  verify: true
+- name: seq-write-1m
+  rw: write
+  blockSize: 1m
+`)
+
+	if err := LoadFioProfiles(path); err != nil {
+		t.Fatalf("LoadFioProfiles returned error: %v", err)
+	}
+	if len(FioProfiles) != 2 {
+		t.Fatalf("expected 2 profiles, got %d", len(FioProfiles))
+	}
+	if !FioProfiles[0].Verify {
+		t.Errorf("expected first profile to have verify enabled: %+v", FioProfiles[0])
+	}
+}
+
+func TestLoadFioProfilesUnsupportedExtension(t *testing.T) {
+	defer func() { FioProfiles = FioDutyCycles }()
+
+	path := writeTempProfileFile(t, "profiles.txt", `[]`)
+	if err := LoadFioProfiles(path); err == nil {
+		t.Fatal("expected an error for an unsupported file extension, got nil")
+	}
+}
+
+func TestLoadFioProfilesEmptyList(t *testing.T) {
+	defer func() { FioProfiles = FioDutyCycles }()
+
+	path := writeTempProfileFile(t, "profiles.json", `[]`)
+	if err := LoadFioProfiles(path); err == nil {
+		t.Fatal("expected an error for an empty profile list, got nil")
+	}
+}
+
+func TestLoadFioProfilesMalformed(t *testing.T) {
+	defer func() { FioProfiles = FioDutyCycles }()
+
+	path := writeTempProfileFile(t, "profiles.json", `{not valid json`)
+	if err := LoadFioProfiles(path); err == nil {
+		t.Fatal("expected an error for a malformed profile file, got nil")
+	}
+}
+
+func TestLoadFioProfilesMissingFile(t *testing.T) {
+	defer func() { FioProfiles = FioDutyCycles }()
+
+	if err := LoadFioProfiles(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected an error for a missing profile file, got nil")
+	}
+}
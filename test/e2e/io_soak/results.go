@@ -0,0 +1,176 @@
+package io_soak
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"time"
+)
+
+// fioLatNs mirrors the slat_ns/clat_ns/lat_ns objects fio emits with
+// --output-format=json. Percentile keys are fio's own string representation,
+// e.g. "50.000000", "95.000000", "99.000000".
+type fioLatNs struct {
+	Min        float64            `json:"min"`
+	Max        float64            `json:"max"`
+	Mean       float64            `json:"mean"`
+	Percentile map[string]float64 `json:"percentile"`
+}
+
+// fioJobStats mirrors the per-direction ("read"/"write") object inside a
+// fio json job result.
+type fioJobStats struct {
+	IOKBytes uint64   `json:"io_kbytes"`
+	BW       uint64   `json:"bw"`
+	IOPS     float64  `json:"iops"`
+	TotalIOs uint64   `json:"total_ios"`
+	ShortIOs uint64   `json:"short_ios"`
+	DropIOs  uint64   `json:"drop_ios"`
+	SlatNs   fioLatNs `json:"slat_ns"`
+	ClatNs   fioLatNs `json:"clat_ns"`
+}
+
+// fioJob mirrors a single entry of the "jobs" array in fio's json output.
+type fioJob struct {
+	JobName string      `json:"jobname"`
+	Error   int         `json:"error"`
+	Read    fioJobStats `json:"read"`
+	Write   fioJobStats `json:"write"`
+}
+
+// fioJSONOutput mirrors the top level object fio writes with --output-format=json.
+type fioJSONOutput struct {
+	TimestampMs int64    `json:"timestamp_ms"`
+	Jobs        []fioJob `json:"jobs"`
+}
+
+// FioResult is the subset of a fio run's json output that the soak test
+// cares about, for one pod and either one iteration of the fixed-iteration
+// run loop or one --status-interval report of a streaming run (numbered
+// from 1 in both cases).
+type FioResult struct {
+	Pod       string
+	Iteration int
+	// Timestamp is when fio produced this report, taken from the report's
+	// own timestamp_ms. Used to join results against NodeDiskStatsCollector
+	// samples gathered over the same run.
+	Timestamp time.Time
+
+	ReadIOPS  float64
+	WriteIOPS float64
+	ReadBwKB  uint64
+	WriteBwKB uint64
+
+	ReadClatP50Us  float64
+	ReadClatP95Us  float64
+	ReadClatP99Us  float64
+	WriteClatP50Us float64
+	WriteClatP95Us float64
+	WriteClatP99Us float64
+
+	ReadSlatUs  float64
+	WriteSlatUs float64
+
+	TotalIOs uint64
+	// DroppedIOs sums fio's short_ios and drop_ios across both directions,
+	// the counters a soak run's "no dropped IOs" SLO should assert against.
+	DroppedIOs uint64
+	Errors     int
+}
+
+// clatPercentileUs extracts a clat percentile, in microseconds, from a fio
+// lat_ns percentile map. fio's percentile keys are printf("%f") formatted,
+// e.g. "50.000000", so the requested percentile is formatted the same way
+// before lookup. Missing percentiles (e.g. no IO of that direction) return 0.
+func clatPercentileUs(lat fioLatNs, percentile float64) float64 {
+	key := strconv.FormatFloat(percentile, 'f', 6, 64)
+	return lat.Percentile[key] / 1000
+}
+
+// fioResultFromJob converts a single parsed fio json job entry into a
+// FioResult for the given pod/iteration, stamped with the report's own
+// timestamp_ms.
+func fioResultFromJob(podName string, iteration int, timestampMs int64, job fioJob) FioResult {
+	return FioResult{
+		Pod:       podName,
+		Iteration: iteration,
+		Timestamp: time.Unix(timestampMs/1000, (timestampMs%1000)*int64(time.Millisecond)),
+		ReadIOPS:  job.Read.IOPS,
+		WriteIOPS: job.Write.IOPS,
+		ReadBwKB:  job.Read.BW,
+		WriteBwKB: job.Write.BW,
+
+		ReadClatP50Us:  clatPercentileUs(job.Read.ClatNs, 50),
+		ReadClatP95Us:  clatPercentileUs(job.Read.ClatNs, 95),
+		ReadClatP99Us:  clatPercentileUs(job.Read.ClatNs, 99),
+		WriteClatP50Us: clatPercentileUs(job.Write.ClatNs, 50),
+		WriteClatP95Us: clatPercentileUs(job.Write.ClatNs, 95),
+		WriteClatP99Us: clatPercentileUs(job.Write.ClatNs, 99),
+
+		ReadSlatUs:  job.Read.SlatNs.Mean / 1000,
+		WriteSlatUs: job.Write.SlatNs.Mean / 1000,
+
+		TotalIOs:   job.Read.TotalIOs + job.Write.TotalIOs,
+		DroppedIOs: job.Read.ShortIOs + job.Read.DropIOs + job.Write.ShortIOs + job.Write.DropIOs,
+		Errors:     job.Error,
+	}
+}
+
+// parseFioJSON parses the contents of a fio --output-format=json report into
+// a FioResult for the given pod/iteration. Mayastor soak jobs run a single
+// fio job per pod, so only the first entry of the "jobs" array is used.
+func parseFioJSON(podName string, iteration int, data []byte) (FioResult, error) {
+	var out fioJSONOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return FioResult{}, fmt.Errorf("failed to parse fio json output for pod %s: %v", podName, err)
+	}
+	if len(out.Jobs) == 0 {
+		return FioResult{}, fmt.Errorf("fio json output for pod %s has no jobs", podName)
+	}
+	return fioResultFromJob(podName, iteration, out.TimestampMs, out.Jobs[0]), nil
+}
+
+// decodeFioJSONStatusStream decodes a sequence of concatenated fio json
+// documents, as written by a run with --status-interval, and returns every
+// complete document seen so far that holds at least one job; documents with
+// an empty "jobs" array are skipped. A trailing partial document, from a
+// file still being written to, is ignored rather than treated as an error.
+func decodeFioJSONStatusStream(data []byte) []fioJSONOutput {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var docs []fioJSONOutput
+	for dec.More() {
+		var out fioJSONOutput
+		if err := dec.Decode(&out); err != nil {
+			break
+		}
+		if len(out.Jobs) > 0 {
+			docs = append(docs, out)
+		}
+	}
+	return docs
+}
+
+// readFioJSONResult reads and parses the fio json report written to path by
+// a run with --output-format=json --output=path.
+func readFioJSONResult(podName string, iteration int, path string) (FioResult, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return FioResult{}, fmt.Errorf("failed to read fio json output for pod %s: %v", podName, err)
+	}
+	return parseFioJSON(podName, iteration, data)
+}
+
+// PrintFioResultsSummary prints a one-line-per-result table of the key IOPS
+// and latency metrics collected across a soak run, for eyeballing or
+// pasting into a test report.
+func PrintFioResultsSummary(results []FioResult) {
+	fmt.Printf("%-24s %5s %10s %10s %10s %10s %10s %10s %10s\n",
+		"POD", "ITER", "R-IOPS", "W-IOPS", "R-BW(KB)", "W-BW(KB)", "R-P99(us)", "W-P99(us)", "ERRORS")
+	for _, r := range results {
+		fmt.Printf("%-24s %5d %10.1f %10.1f %10d %10d %10.1f %10.1f %10d\n",
+			r.Pod, r.Iteration, r.ReadIOPS, r.WriteIOPS, r.ReadBwKB, r.WriteBwKB,
+			r.ReadClatP99Us, r.WriteClatP99Us, r.Errors)
+	}
+}
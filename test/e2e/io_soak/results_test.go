@@ -0,0 +1,152 @@
+package io_soak
+
+import (
+	"testing"
+)
+
+func TestClatPercentileUs(t *testing.T) {
+	lat := fioLatNs{
+		Percentile: map[string]float64{
+			"50.000000": 1000,
+			"99.000000": 5000,
+		},
+	}
+
+	if got := clatPercentileUs(lat, 50); got != 1 {
+		t.Errorf("p50: expected 1us, got %v", got)
+	}
+	if got := clatPercentileUs(lat, 99); got != 5 {
+		t.Errorf("p99: expected 5us, got %v", got)
+	}
+	if got := clatPercentileUs(lat, 95); got != 0 {
+		t.Errorf("missing percentile: expected 0, got %v", got)
+	}
+}
+
+const sampleFioJSON = `{
+	"timestamp_ms": 1700000000000,
+	"jobs": [
+		{
+			"jobname": "fio",
+			"error": 0,
+			"read": {
+				"io_kbytes": 1024,
+				"bw": 512,
+				"iops": 128.5,
+				"total_ios": 100,
+				"slat_ns": {"mean": 1000},
+				"clat_ns": {"percentile": {"50.000000": 2000, "95.000000": 4000, "99.000000": 8000}}
+			},
+			"write": {
+				"io_kbytes": 2048,
+				"bw": 256,
+				"iops": 64.5,
+				"total_ios": 50,
+				"slat_ns": {"mean": 1500},
+				"clat_ns": {"percentile": {"50.000000": 3000, "95.000000": 6000, "99.000000": 9000}}
+			}
+		}
+	]
+}`
+
+func TestParseFioJSON(t *testing.T) {
+	result, err := parseFioJSON("fio-pod-0", 3, []byte(sampleFioJSON))
+	if err != nil {
+		t.Fatalf("parseFioJSON returned error: %v", err)
+	}
+
+	if result.Pod != "fio-pod-0" || result.Iteration != 3 {
+		t.Errorf("unexpected identity fields: %+v", result)
+	}
+	if result.ReadIOPS != 128.5 || result.WriteIOPS != 64.5 {
+		t.Errorf("unexpected iops: %+v", result)
+	}
+	if result.ReadBwKB != 512 || result.WriteBwKB != 256 {
+		t.Errorf("unexpected bw: %+v", result)
+	}
+	if result.ReadClatP99Us != 8 || result.WriteClatP99Us != 9 {
+		t.Errorf("unexpected clat p99: %+v", result)
+	}
+	if result.TotalIOs != 150 {
+		t.Errorf("expected total_ios 150, got %d", result.TotalIOs)
+	}
+	if result.Timestamp.UnixNano()/int64(1e6) != 1700000000000 {
+		t.Errorf("unexpected timestamp: %v", result.Timestamp)
+	}
+}
+
+const sampleFioJSONWithDrops = `{
+	"timestamp_ms": 1700000000000,
+	"jobs": [
+		{
+			"jobname": "fio",
+			"error": 0,
+			"read": {
+				"io_kbytes": 1024,
+				"bw": 512,
+				"iops": 128.5,
+				"total_ios": 100,
+				"short_ios": 2,
+				"drop_ios": 1,
+				"slat_ns": {"mean": 1000},
+				"clat_ns": {"percentile": {"50.000000": 2000, "95.000000": 4000, "99.000000": 8000}}
+			},
+			"write": {
+				"io_kbytes": 2048,
+				"bw": 256,
+				"iops": 64.5,
+				"total_ios": 50,
+				"short_ios": 3,
+				"drop_ios": 4,
+				"slat_ns": {"mean": 1500},
+				"clat_ns": {"percentile": {"50.000000": 3000, "95.000000": 6000, "99.000000": 9000}}
+			}
+		}
+	]
+}`
+
+func TestParseFioJSONDroppedIOs(t *testing.T) {
+	result, err := parseFioJSON("fio-pod-0", 1, []byte(sampleFioJSONWithDrops))
+	if err != nil {
+		t.Fatalf("parseFioJSON returned error: %v", err)
+	}
+
+	// 2 read short_ios + 1 read drop_ios + 3 write short_ios + 4 write drop_ios.
+	if result.DroppedIOs != 10 {
+		t.Errorf("expected DroppedIOs 10, got %d", result.DroppedIOs)
+	}
+}
+
+func TestParseFioJSONMalformed(t *testing.T) {
+	if _, err := parseFioJSON("fio-pod-0", 1, []byte(`not json`)); err == nil {
+		t.Fatal("expected an error for malformed json, got nil")
+	}
+}
+
+func TestParseFioJSONNoJobs(t *testing.T) {
+	if _, err := parseFioJSON("fio-pod-0", 1, []byte(`{"jobs": []}`)); err == nil {
+		t.Fatal("expected an error when jobs is empty, got nil")
+	}
+}
+
+func TestDecodeFioJSONStatusStream(t *testing.T) {
+	// Two complete status-interval documents back to back, as fio would
+	// append them, plus a trailing partial document still being written.
+	stream := sampleFioJSON + sampleFioJSON + `{"timestamp_ms": 170`
+
+	docs := decodeFioJSONStatusStream([]byte(stream))
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 decoded documents, got %d", len(docs))
+	}
+	for i, d := range docs {
+		if len(d.Jobs) != 1 {
+			t.Errorf("doc %d: expected 1 job, got %d", i, len(d.Jobs))
+		}
+	}
+}
+
+func TestDecodeFioJSONStatusStreamEmpty(t *testing.T) {
+	if docs := decodeFioJSONStatusStream([]byte{}); len(docs) != 0 {
+		t.Errorf("expected no documents for empty input, got %d", len(docs))
+	}
+}
@@ -0,0 +1,101 @@
+package io_soak
+
+import (
+	"e2e-basic/common"
+
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+const (
+	// defaultVerifyBacklog is used when a profile enables Verify but leaves
+	// VerifyBacklog at zero.
+	defaultVerifyBacklog = 1024
+	// defaultVerifyPattern is used when a profile enables Verify on a
+	// randwrite workload but leaves VerifyPattern empty.
+	defaultVerifyPattern = "0xdeadbeef"
+)
+
+// verifyArgsForProfile builds the fio data-integrity verification args for
+// profile, or nil if profile.Verify is not set. This applies equally to
+// filesystem and raw-block targets, fio does not distinguish between them
+// for verification.
+func verifyArgsForProfile(profile FioProfile) []string {
+	if !profile.Verify {
+		return nil
+	}
+
+	backlog := profile.VerifyBacklog
+	if backlog <= 0 {
+		backlog = defaultVerifyBacklog
+	}
+
+	args := []string{
+		"--do_verify=1",
+		"--verify=crc32c",
+		"--verify_fatal=1",
+		fmt.Sprintf("--verify_backlog=%d", backlog),
+	}
+
+	if profile.Rw == "randwrite" {
+		pattern := profile.VerifyPattern
+		if pattern == "" {
+			pattern = defaultVerifyPattern
+		}
+		args = append(args, fmt.Sprintf("--verify_pattern=%s", pattern))
+	}
+
+	return args
+}
+
+// verifyOffsetRe matches fio's own verify-failure message and captures the
+// offending offset, e.g. "crc32c: verify failed at file foo offset
+// 1048576, length 4096". It is deliberately anchored to that message rather
+// than to any bare "offset" token, since a full soak run's captured output
+// can contain unrelated occurrences of the word earlier in the log.
+var verifyOffsetRe = regexp.MustCompile(`(?i)verify(?:\s+failed|_\w+)?[^\n]*\boffset[:=]?\s*(\d+)`)
+
+// verifyError wraps an error raised by a fio verify failure with the
+// offending offset, when one could be extracted from fio's stderr.
+type verifyError struct {
+	pod    string
+	offset int64
+	hasOff bool
+	cause  error
+}
+
+func (e *verifyError) Error() string {
+	if e.hasOff {
+		return fmt.Sprintf("data corruption detected on pod %s at offset %d: %v", e.pod, e.offset, e.cause)
+	}
+	return fmt.Sprintf("data corruption detected on pod %s: %v", e.pod, e.cause)
+}
+
+func (e *verifyError) Unwrap() error {
+	return e.cause
+}
+
+// newVerifyError builds a verifyError for podName, pulling the offending
+// offset out of fio's stderr if fio reported one. combinedOutput (stdout and
+// stderr from the whole run, as captured to /tmp/<pod>.out) is only used as
+// a fallback when cause doesn't carry stderr separately, since it may
+// contain status/json noise that happens to precede the real verify failure.
+func newVerifyError(podName string, combinedOutput []byte, cause error) error {
+	ve := &verifyError{pod: podName, cause: cause}
+
+	text := combinedOutput
+	var execErr *common.FioExecError
+	if errors.As(cause, &execErr) {
+		text = execErr.Stderr
+	}
+
+	if m := verifyOffsetRe.FindSubmatch(text); m != nil {
+		if off, err := strconv.ParseInt(string(m[1]), 10, 64); err == nil {
+			ve.offset = off
+			ve.hasOff = true
+		}
+	}
+	return ve
+}
@@ -0,0 +1,95 @@
+package io_soak
+
+import (
+	"e2e-basic/common"
+
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyArgsForProfileDisabled(t *testing.T) {
+	if args := verifyArgsForProfile(FioProfile{}); args != nil {
+		t.Errorf("expected no args when Verify is false, got %v", args)
+	}
+}
+
+func TestVerifyArgsForProfileDefaults(t *testing.T) {
+	args := verifyArgsForProfile(FioProfile{Verify: true, Rw: "randwrite"})
+
+	want := []string{"--do_verify=1", "--verify=crc32c", "--verify_fatal=1", "--verify_backlog=1024", "--verify_pattern=0xdeadbeef"}
+	if strings.Join(args, " ") != strings.Join(want, " ") {
+		t.Errorf("unexpected args:\n got: %v\nwant: %v", args, want)
+	}
+}
+
+func TestVerifyArgsForProfileOverrides(t *testing.T) {
+	args := verifyArgsForProfile(FioProfile{
+		Verify:        true,
+		Rw:            "randwrite",
+		VerifyBacklog: 256,
+		VerifyPattern: "0x1234",
+	})
+
+	want := []string{"--do_verify=1", "--verify=crc32c", "--verify_fatal=1", "--verify_backlog=256", "--verify_pattern=0x1234"}
+	if strings.Join(args, " ") != strings.Join(want, " ") {
+		t.Errorf("unexpected args:\n got: %v\nwant: %v", args, want)
+	}
+}
+
+func TestVerifyArgsForProfileNoPatternForNonRandwrite(t *testing.T) {
+	args := verifyArgsForProfile(FioProfile{Verify: true, Rw: "read"})
+	for _, a := range args {
+		if strings.HasPrefix(a, "--verify_pattern") {
+			t.Errorf("did not expect --verify_pattern for a read workload, got %v", args)
+		}
+	}
+}
+
+func TestNewVerifyErrorExtractsOffsetFromFioExecErrorStderr(t *testing.T) {
+	cause := &common.FioExecError{
+		Stderr: []byte("some earlier log mentions an unrelated offset 99\ncrc32c: verify failed at file foo offset 1048576, length 4096\n"),
+	}
+
+	err := newVerifyError("fio-pod-0", nil, cause)
+	ve, ok := err.(*verifyError)
+	if !ok {
+		t.Fatalf("expected *verifyError, got %T", err)
+	}
+	if !ve.hasOff || ve.offset != 1048576 {
+		t.Errorf("expected offset 1048576, got hasOff=%v offset=%v", ve.hasOff, ve.offset)
+	}
+}
+
+func TestNewVerifyErrorFallsBackToCombinedOutput(t *testing.T) {
+	combined := []byte("crc32c: verify failed at file foo offset 2048, length 4096\n")
+	err := newVerifyError("fio-pod-0", combined, errors.New("exit status 1"))
+
+	ve, ok := err.(*verifyError)
+	if !ok {
+		t.Fatalf("expected *verifyError, got %T", err)
+	}
+	if !ve.hasOff || ve.offset != 2048 {
+		t.Errorf("expected offset 2048, got hasOff=%v offset=%v", ve.hasOff, ve.offset)
+	}
+}
+
+func TestNewVerifyErrorIgnoresUnrelatedOffset(t *testing.T) {
+	combined := []byte("an unrelated log line mentions offset 99 with no verify failure nearby\n")
+	err := newVerifyError("fio-pod-0", combined, errors.New("exit status 1"))
+
+	ve, ok := err.(*verifyError)
+	if !ok {
+		t.Fatalf("expected *verifyError, got %T", err)
+	}
+	if ve.hasOff {
+		t.Errorf("expected no offset to be extracted, got %v", ve.offset)
+	}
+}
+
+func TestNewVerifyErrorNoOffset(t *testing.T) {
+	err := newVerifyError("fio-pod-0", []byte("some unrelated failure"), errors.New("exit status 1"))
+	if !strings.Contains(err.Error(), "data corruption detected on pod fio-pod-0:") {
+		t.Errorf("unexpected error message: %v", err)
+	}
+}
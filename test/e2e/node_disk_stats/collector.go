@@ -0,0 +1,144 @@
+package node_disk_stats
+
+import (
+	"e2e-basic/common"
+
+	"fmt"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DiskStatsSample is one interval's worth of /proc/diskstats derived metrics
+// for a single block device on a single Mayastor storage node.
+type DiskStatsSample struct {
+	Node      string
+	Device    string
+	Timestamp time.Time
+
+	// IoAwaitMs is the average time, in ms, an IO spent queued plus serviced
+	// over the interval (the diskstats "await" metric).
+	IoAwaitMs float64
+	// IoUtilPct is the percentage of the interval the device had at least
+	// one IO in flight (the diskstats "%util" metric).
+	IoUtilPct float64
+	// IoSvctmMs is the average service time, in ms, per IO over the
+	// interval. Deprecated by the kernel but still useful as a coarse
+	// signal, kept here for parity with iostat output.
+	IoSvctmMs float64
+
+	MergedReads  uint64
+	MergedWrites uint64
+	// QueueDepth is the instantaneous number of IOs in flight at the end of
+	// the interval (diskstats field 9, ios_in_progress).
+	QueueDepth uint64
+}
+
+// NodeDiskStatsCollector periodically scrapes /proc/diskstats on a set of
+// Mayastor storage nodes for a set of underlying block devices, for the
+// duration of an io_soak run, so that guest-visible latency spikes can be
+// correlated with host-side device saturation. Start it before the fio
+// goroutines are launched and Stop it once they signal completion or error
+// on doneC/errC.
+type NodeDiskStatsCollector struct {
+	nodes    []string
+	devices  map[string]bool
+	interval time.Duration
+
+	mu      sync.Mutex
+	samples []DiskStatsSample
+	prev    map[string]map[string]diskStatsLine // node -> device -> last reading
+
+	stopC chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewNodeDiskStatsCollector builds a collector for the given storage nodes
+// and device names (e.g. "nvme0n1"), polling every interval.
+func NewNodeDiskStatsCollector(nodes []string, devices []string, interval time.Duration) *NodeDiskStatsCollector {
+	deviceSet := make(map[string]bool, len(devices))
+	for _, d := range devices {
+		deviceSet[d] = true
+	}
+	return &NodeDiskStatsCollector{
+		nodes:    nodes,
+		devices:  deviceSet,
+		interval: interval,
+		prev:     make(map[string]map[string]diskStatsLine),
+		stopC:    make(chan struct{}),
+	}
+}
+
+// Start launches one polling goroutine per node and returns immediately.
+func (c *NodeDiskStatsCollector) Start() {
+	for _, node := range c.nodes {
+		c.wg.Add(1)
+		go c.run(node)
+	}
+}
+
+// Stop halts all polling goroutines, waits for them to exit, and returns the
+// samples collected so far.
+func (c *NodeDiskStatsCollector) Stop() []DiskStatsSample {
+	close(c.stopC)
+	c.wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.samples
+}
+
+func (c *NodeDiskStatsCollector) run(node string) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopC:
+			return
+		case <-ticker.C:
+			if err := c.poll(node); err != nil {
+				logf.Log.Info("Failed to scrape diskstats", "node", node, "error", err)
+			}
+		}
+	}
+}
+
+func (c *NodeDiskStatsCollector) poll(node string) error {
+	output, err := common.RunOnNode(node, "cat", "/proc/diskstats")
+	if err != nil {
+		return fmt.Errorf("failed to read /proc/diskstats on node %s: %v", node, err)
+	}
+
+	lines, err := parseDiskStats(output, c.devices)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prevForNode := c.prev[node]
+	if prevForNode == nil {
+		prevForNode = map[string]diskStatsLine{}
+	}
+
+	for device, cur := range lines {
+		prev, ok := prevForNode[device]
+		prevForNode[device] = cur
+		if !ok {
+			// first reading for this device, nothing to delta against yet.
+			continue
+		}
+		sample := deltaDiskStatsSample(node, prev, cur, float64(c.interval.Milliseconds()))
+		sample.Timestamp = now
+		c.samples = append(c.samples, sample)
+	}
+	c.prev[node] = prevForNode
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package node_disk_stats
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// diskStatsLine holds the cumulative counters for one device, read from a
+// single line of /proc/diskstats. Only the first 14 fields are read, they
+// are present on every kernel since 2.6.25; later kernels append discard
+// and flush counters this soak report does not need.
+// See https://www.kernel.org/doc/Documentation/iostats.txt
+type diskStatsLine struct {
+	device string
+
+	readsCompleted uint64
+	readsMerged    uint64
+	sectorsRead    uint64
+	msReading      uint64
+
+	writesCompleted uint64
+	writesMerged    uint64
+	sectorsWritten  uint64
+	msWriting       uint64
+
+	iosInProgress   uint64
+	msDoingIO       uint64
+	weightedMsDoing uint64
+}
+
+// parseDiskStats parses the contents of /proc/diskstats, returning the
+// counters for the requested devices. Devices not present in the output are
+// silently omitted, e.g. because they haven't been attached yet.
+func parseDiskStats(data []byte, devices map[string]bool) (map[string]diskStatsLine, error) {
+	lines := map[string]diskStatsLine{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 14 {
+			continue
+		}
+		device := fields[2]
+		if !devices[device] {
+			continue
+		}
+
+		values := make([]uint64, 11)
+		for i := range values {
+			v, err := strconv.ParseUint(fields[3+i], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse /proc/diskstats field %d for device %s: %v", 3+i, device, err)
+			}
+			values[i] = v
+		}
+
+		lines[device] = diskStatsLine{
+			device:          device,
+			readsCompleted:  values[0],
+			readsMerged:     values[1],
+			sectorsRead:     values[2],
+			msReading:       values[3],
+			writesCompleted: values[4],
+			writesMerged:    values[5],
+			sectorsWritten:  values[6],
+			msWriting:       values[7],
+			iosInProgress:   values[8],
+			msDoingIO:       values[9],
+			weightedMsDoing: values[10],
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan /proc/diskstats: %v", err)
+	}
+	return lines, nil
+}
+
+// deltaDiskStatsSample derives the interval metrics of interest from two
+// diskStatsLine snapshots of the same device, elapsedMs apart.
+func deltaDiskStatsSample(node string, prev, cur diskStatsLine, elapsedMs float64) DiskStatsSample {
+	reads := cur.readsCompleted - prev.readsCompleted
+	writes := cur.writesCompleted - prev.writesCompleted
+	ios := reads + writes
+
+	msReadWrite := float64((cur.msReading - prev.msReading) + (cur.msWriting - prev.msWriting))
+	msDoingIO := float64(cur.msDoingIO - prev.msDoingIO)
+
+	sample := DiskStatsSample{
+		Node:         node,
+		Device:       cur.device,
+		MergedReads:  cur.readsMerged - prev.readsMerged,
+		MergedWrites: cur.writesMerged - prev.writesMerged,
+		QueueDepth:   cur.iosInProgress,
+		IoUtilPct:    100 * msDoingIO / elapsedMs,
+	}
+	if ios > 0 {
+		sample.IoAwaitMs = msReadWrite / float64(ios)
+		sample.IoSvctmMs = msDoingIO / float64(ios)
+	}
+	return sample
+}
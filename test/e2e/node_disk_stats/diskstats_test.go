@@ -0,0 +1,106 @@
+package node_disk_stats
+
+import (
+	"testing"
+)
+
+const sampleDiskStats = `   8       0 sda 100 10 2000 500 50 5 1000 300 0 200 800
+   8       1 sda1 90 5 1800 400 40 2 900 200 0 150 600
+ 259       0 nvme0n1 1000 100 50000 2000 900 90 45000 1800 2 1500 3500
+`
+
+func TestParseDiskStats(t *testing.T) {
+	devices := map[string]bool{"sda": true, "nvme0n1": true}
+	lines, err := parseDiskStats([]byte(sampleDiskStats), devices)
+	if err != nil {
+		t.Fatalf("parseDiskStats returned error: %v", err)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 devices, got %d: %v", len(lines), lines)
+	}
+	if lines["sda"].readsCompleted != 100 || lines["sda"].writesCompleted != 50 {
+		t.Errorf("unexpected sda counters: %+v", lines["sda"])
+	}
+	if lines["nvme0n1"].iosInProgress != 2 {
+		t.Errorf("unexpected nvme0n1 queue depth: %+v", lines["nvme0n1"])
+	}
+	if _, ok := lines["sda1"]; ok {
+		t.Errorf("did not request sda1, but it was returned")
+	}
+}
+
+func TestParseDiskStatsIgnoresShortLines(t *testing.T) {
+	lines, err := parseDiskStats([]byte("8 0 sda 100 10\n"), map[string]bool{"sda": true})
+	if err != nil {
+		t.Fatalf("parseDiskStats returned error: %v", err)
+	}
+	if len(lines) != 0 {
+		t.Errorf("expected short lines to be skipped, got %v", lines)
+	}
+}
+
+func TestParseDiskStatsMalformedCounter(t *testing.T) {
+	bad := "8 0 sda notanumber 10 2000 500 50 5 1000 300 0 200 800\n"
+	if _, err := parseDiskStats([]byte(bad), map[string]bool{"sda": true}); err == nil {
+		t.Fatal("expected an error for a non-numeric counter, got nil")
+	}
+}
+
+func TestDeltaDiskStatsSample(t *testing.T) {
+	prev := diskStatsLine{
+		device:          "nvme0n1",
+		readsCompleted:  1000,
+		readsMerged:     100,
+		writesCompleted: 900,
+		writesMerged:    90,
+		msReading:       2000,
+		msWriting:       45000,
+		msDoingIO:       1500,
+		iosInProgress:   2,
+	}
+	cur := diskStatsLine{
+		device:          "nvme0n1",
+		readsCompleted:  1100,
+		readsMerged:     110,
+		writesCompleted: 1000,
+		writesMerged:    95,
+		msReading:       2200,
+		msWriting:       46000,
+		msDoingIO:       2500,
+		iosInProgress:   5,
+	}
+
+	sample := deltaDiskStatsSample("node-1", prev, cur, 1000)
+
+	if sample.Node != "node-1" || sample.Device != "nvme0n1" {
+		t.Errorf("unexpected identity fields: %+v", sample)
+	}
+	if sample.MergedReads != 10 || sample.MergedWrites != 5 {
+		t.Errorf("unexpected merge counts: %+v", sample)
+	}
+	if sample.QueueDepth != 5 {
+		t.Errorf("expected queue depth 5, got %d", sample.QueueDepth)
+	}
+	// (200ms read + 1000ms write) / (100 reads + 100 writes) = 6ms await
+	if sample.IoAwaitMs != 6 {
+		t.Errorf("expected io_await 6ms, got %v", sample.IoAwaitMs)
+	}
+	// 1000ms doing io / 1000ms elapsed * 100 = 100% util
+	if sample.IoUtilPct != 100 {
+		t.Errorf("expected io_util 100%%, got %v", sample.IoUtilPct)
+	}
+	// 1000ms doing io / 200 ios = 5ms svctm
+	if sample.IoSvctmMs != 5 {
+		t.Errorf("expected io_svctm 5ms, got %v", sample.IoSvctmMs)
+	}
+}
+
+func TestDeltaDiskStatsSampleNoIO(t *testing.T) {
+	line := diskStatsLine{device: "nvme0n1"}
+	sample := deltaDiskStatsSample("node-1", line, line, 1000)
+
+	if sample.IoAwaitMs != 0 || sample.IoSvctmMs != 0 {
+		t.Errorf("expected zero await/svctm with no IO delta, got %+v", sample)
+	}
+}
@@ -0,0 +1,81 @@
+package node_disk_stats
+
+import (
+	"e2e-basic/io_soak"
+
+	"fmt"
+	"time"
+)
+
+// CorrelatedSample links one per-pod fio result to the closest-in-time
+// diskstats sample for a given storage node/device, so a soak report can
+// point at the host-side saturation behind a guest-visible latency spike.
+type CorrelatedSample struct {
+	io_soak.FioResult
+	DiskStatsSample
+	// SkewMs is the time difference, in ms, between the fio result and the
+	// diskstats sample it was matched against.
+	SkewMs float64
+}
+
+// PodLocation identifies the storage node and underlying block device
+// backing a soak pod's volume, so its fio results can be correlated against
+// diskstats from that node/device and no other.
+type PodLocation struct {
+	Node   string
+	Device string
+}
+
+// CorrelateFioAndDiskStats joins fioResults against diskSamples by nearest
+// timestamp, restricted to the node/device each pod's volume is actually
+// hosted on per podLocations, discarding matches further apart than window.
+// Soak runs should pass a window on the order of the fio --status-interval
+// and the diskstats poll interval, whichever is larger. A fio result whose
+// pod has no entry in podLocations is skipped, since there is no candidate
+// diskstats sample it could be correctly matched against.
+func CorrelateFioAndDiskStats(fioResults []io_soak.FioResult, diskSamples []DiskStatsSample, podLocations map[string]PodLocation, window time.Duration) []CorrelatedSample {
+	var correlated []CorrelatedSample
+
+	for _, fr := range fioResults {
+		loc, ok := podLocations[fr.Pod]
+		if !ok {
+			continue
+		}
+
+		best, bestSkew, found := DiskStatsSample{}, window, false
+		for _, ds := range diskSamples {
+			if ds.Node != loc.Node || ds.Device != loc.Device {
+				continue
+			}
+			skew := fr.Timestamp.Sub(ds.Timestamp)
+			if skew < 0 {
+				skew = -skew
+			}
+			if skew <= bestSkew {
+				best, bestSkew, found = ds, skew, true
+			}
+		}
+		if !found {
+			continue
+		}
+		correlated = append(correlated, CorrelatedSample{
+			FioResult:       fr,
+			DiskStatsSample: best,
+			SkewMs:          float64(bestSkew.Milliseconds()),
+		})
+	}
+
+	return correlated
+}
+
+// PrintCorrelatedSummary prints a one-line-per-sample table linking each
+// fio result to the host-side device metrics recorded around the same time.
+func PrintCorrelatedSummary(samples []CorrelatedSample) {
+	fmt.Printf("%-24s %5s %-12s %-16s %10s %10s %10s %10s\n",
+		"POD", "ITER", "NODE", "DEVICE", "W-P99(us)", "AWAIT(ms)", "UTIL(%)", "QDEPTH")
+	for _, s := range samples {
+		fmt.Printf("%-24s %5d %-12s %-16s %10.1f %10.2f %10.1f %10d\n",
+			s.Pod, s.Iteration, s.Node, s.Device,
+			s.WriteClatP99Us, s.IoAwaitMs, s.IoUtilPct, s.QueueDepth)
+	}
+}
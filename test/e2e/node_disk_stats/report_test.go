@@ -0,0 +1,106 @@
+package node_disk_stats
+
+import (
+	"e2e-basic/io_soak"
+
+	"testing"
+	"time"
+)
+
+func TestCorrelateFioAndDiskStats(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+
+	fioResults := []io_soak.FioResult{
+		{Pod: "fio-0", Iteration: 1, Timestamp: base},
+		{Pod: "fio-0", Iteration: 2, Timestamp: base.Add(5 * time.Second)},
+	}
+	diskSamples := []DiskStatsSample{
+		{Node: "node-1", Device: "nvme0n1", Timestamp: base.Add(200 * time.Millisecond), IoAwaitMs: 1},
+		{Node: "node-1", Device: "nvme0n1", Timestamp: base.Add(5100 * time.Millisecond), IoAwaitMs: 9},
+	}
+	podLocations := map[string]PodLocation{"fio-0": {Node: "node-1", Device: "nvme0n1"}}
+
+	correlated := CorrelateFioAndDiskStats(fioResults, diskSamples, podLocations, time.Second)
+	if len(correlated) != 2 {
+		t.Fatalf("expected 2 correlated samples, got %d", len(correlated))
+	}
+	if correlated[0].IoAwaitMs != 1 {
+		t.Errorf("expected first result matched to first disk sample, got %+v", correlated[0])
+	}
+	if correlated[1].IoAwaitMs != 9 {
+		t.Errorf("expected second result matched to second disk sample, got %+v", correlated[1])
+	}
+}
+
+// TestCorrelateFioAndDiskStatsMultiNode reproduces a multi-node soak run
+// where every node's poller ticks at the same cadence: without filtering by
+// the pod's actual node/device, the nearest-timestamp sample from an
+// unrelated node would be picked instead.
+func TestCorrelateFioAndDiskStatsMultiNode(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+
+	fioResults := []io_soak.FioResult{
+		{Pod: "fio-on-node-1", Iteration: 1, Timestamp: base},
+		{Pod: "fio-on-node-2", Iteration: 1, Timestamp: base},
+	}
+	// Both nodes' samples land at the same timestamp, so a global
+	// nearest-timestamp match is ambiguous and must be broken by location.
+	diskSamples := []DiskStatsSample{
+		{Node: "node-1", Device: "nvme0n1", Timestamp: base, IoAwaitMs: 1},
+		{Node: "node-2", Device: "nvme0n1", Timestamp: base, IoAwaitMs: 99},
+	}
+	podLocations := map[string]PodLocation{
+		"fio-on-node-1": {Node: "node-1", Device: "nvme0n1"},
+		"fio-on-node-2": {Node: "node-2", Device: "nvme0n1"},
+	}
+
+	correlated := CorrelateFioAndDiskStats(fioResults, diskSamples, podLocations, time.Second)
+	if len(correlated) != 2 {
+		t.Fatalf("expected 2 correlated samples, got %d", len(correlated))
+	}
+
+	byPod := map[string]CorrelatedSample{}
+	for _, c := range correlated {
+		byPod[c.Pod] = c
+	}
+
+	if got := byPod["fio-on-node-1"]; got.Node != "node-1" || got.IoAwaitMs != 1 {
+		t.Errorf("fio-on-node-1: expected node-1's sample, got %+v", got)
+	}
+	if got := byPod["fio-on-node-2"]; got.Node != "node-2" || got.IoAwaitMs != 99 {
+		t.Errorf("fio-on-node-2: expected node-2's sample, got %+v", got)
+	}
+}
+
+func TestCorrelateFioAndDiskStatsOutsideWindow(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+
+	fioResults := []io_soak.FioResult{{Pod: "fio-0", Iteration: 1, Timestamp: base}}
+	diskSamples := []DiskStatsSample{{Node: "node-1", Device: "nvme0n1", Timestamp: base.Add(10 * time.Second)}}
+	podLocations := map[string]PodLocation{"fio-0": {Node: "node-1", Device: "nvme0n1"}}
+
+	correlated := CorrelateFioAndDiskStats(fioResults, diskSamples, podLocations, time.Second)
+	if len(correlated) != 0 {
+		t.Errorf("expected no matches outside the window, got %d", len(correlated))
+	}
+}
+
+func TestCorrelateFioAndDiskStatsNoSamples(t *testing.T) {
+	fioResults := []io_soak.FioResult{{Pod: "fio-0", Iteration: 1, Timestamp: time.Unix(1700000000, 0)}}
+	podLocations := map[string]PodLocation{"fio-0": {Node: "node-1", Device: "nvme0n1"}}
+
+	correlated := CorrelateFioAndDiskStats(fioResults, nil, podLocations, time.Second)
+	if len(correlated) != 0 {
+		t.Errorf("expected no matches with no disk samples, got %d", len(correlated))
+	}
+}
+
+func TestCorrelateFioAndDiskStatsUnknownPod(t *testing.T) {
+	fioResults := []io_soak.FioResult{{Pod: "fio-unknown", Iteration: 1, Timestamp: time.Unix(1700000000, 0)}}
+	diskSamples := []DiskStatsSample{{Node: "node-1", Device: "nvme0n1", Timestamp: time.Unix(1700000000, 0)}}
+
+	correlated := CorrelateFioAndDiskStats(fioResults, diskSamples, map[string]PodLocation{}, time.Second)
+	if len(correlated) != 0 {
+		t.Errorf("expected no matches for a pod missing from podLocations, got %d", len(correlated))
+	}
+}